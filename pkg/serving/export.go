@@ -0,0 +1,111 @@
+// Copyright © 2019 The Knative Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package serving
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"knative.dev/serving/pkg/apis/serving/v1alpha1"
+)
+
+// exportScrubbedAnnotations are populated by the controller and are either
+// meaningless or actively wrong if replayed through a Create call.
+var exportScrubbedAnnotations = []string{
+	"serving.knative.dev/creator",
+	"serving.knative.dev/lastModifier",
+	"kubectl.kubernetes.io/last-applied-configuration",
+}
+
+// SanitizeForExport strips server-populated fields from meta so the owning
+// object round-trips cleanly through Create: identity/bookkeeping fields
+// (UID, ResourceVersion, Generation, CreationTimestamp, ManagedFields,
+// SelfLink) and controller-owned annotations that only make sense on the
+// original object. Shared by `kn service export` and `kn revision export`.
+func SanitizeForExport(meta *metav1.ObjectMeta) {
+	meta.UID = ""
+	meta.ResourceVersion = ""
+	meta.Generation = 0
+	meta.CreationTimestamp = metav1.Time{}
+	meta.ManagedFields = nil
+	meta.SelfLink = ""
+	for _, key := range exportScrubbedAnnotations {
+		delete(meta.Annotations, key)
+	}
+}
+
+// SanitizeServiceForExport returns a deep copy of service with its status
+// cleared and all server-populated metadata, including that of its revision
+// template if it has one, scrubbed via SanitizeForExport.
+func SanitizeServiceForExport(service *v1alpha1.Service) *v1alpha1.Service {
+	out := service.DeepCopy()
+	SanitizeForExport(&out.ObjectMeta)
+	if template, err := RevisionTemplateOfService(out); err == nil {
+		SanitizeForExport(&template.ObjectMeta)
+		sanitizePodSpec(&template.Spec.PodSpec)
+	}
+	out.Status = v1alpha1.ServiceStatus{}
+	return out
+}
+
+// SanitizeRevisionForExport returns a deep copy of revision with its status
+// cleared and all server-populated metadata scrubbed, suitable for
+// embedding in exported manifests alongside its owning Service.
+func SanitizeRevisionForExport(revision *v1alpha1.Revision) *v1alpha1.Revision {
+	out := revision.DeepCopy()
+	SanitizeForExport(&out.ObjectMeta)
+	sanitizePodSpec(&out.Spec.PodSpec)
+	out.Status = v1alpha1.RevisionStatus{}
+	return out
+}
+
+// sanitizePodSpec drops projected volumes that have a service-account token
+// source (and their mounts), such as the ones the API server injects at
+// admission time, so they aren't replayed on Create. Other volumes are kept.
+func sanitizePodSpec(podSpec *corev1.PodSpec) {
+	var volumes []corev1.Volume
+	dropped := map[string]bool{}
+	for _, vol := range podSpec.Volumes {
+		if vol.Projected != nil && isServiceAccountTokenVolume(vol.Projected) {
+			dropped[vol.Name] = true
+			continue
+		}
+		volumes = append(volumes, vol)
+	}
+	podSpec.Volumes = volumes
+	for i := range podSpec.Containers {
+		podSpec.Containers[i].VolumeMounts = dropMounts(podSpec.Containers[i].VolumeMounts, dropped)
+	}
+}
+
+func isServiceAccountTokenVolume(projected *corev1.ProjectedVolumeSource) bool {
+	for _, source := range projected.Sources {
+		if source.ServiceAccountToken != nil {
+			return true
+		}
+	}
+	return false
+}
+
+func dropMounts(mounts []corev1.VolumeMount, dropped map[string]bool) []corev1.VolumeMount {
+	var kept []corev1.VolumeMount
+	for _, m := range mounts {
+		if dropped[m.Name] {
+			continue
+		}
+		kept = append(kept, m)
+	}
+	return kept
+}
@@ -0,0 +1,123 @@
+// Copyright © 2019 The Knative Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package serving
+
+import (
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	"knative.dev/serving/pkg/apis/serving/v1alpha1"
+)
+
+func TestSanitizeForExport(t *testing.T) {
+	meta := metav1.ObjectMeta{
+		Name:              "my-service",
+		UID:               types.UID("some-uid"),
+		ResourceVersion:   "12345",
+		Generation:        3,
+		CreationTimestamp: metav1.Now(),
+		SelfLink:          "/apis/serving.knative.dev/v1alpha1/namespaces/default/services/my-service",
+		Annotations: map[string]string{
+			"serving.knative.dev/creator":                      "alice",
+			"serving.knative.dev/lastModifier":                 "bob",
+			"kubectl.kubernetes.io/last-applied-configuration": "{}",
+			"my-own-annotation":                                "keep-me",
+		},
+	}
+
+	SanitizeForExport(&meta)
+
+	if meta.UID != "" {
+		t.Errorf("UID = %q, want empty", meta.UID)
+	}
+	if meta.ResourceVersion != "" {
+		t.Errorf("ResourceVersion = %q, want empty", meta.ResourceVersion)
+	}
+	if meta.Generation != 0 {
+		t.Errorf("Generation = %d, want 0", meta.Generation)
+	}
+	if !meta.CreationTimestamp.IsZero() {
+		t.Errorf("CreationTimestamp = %v, want zero", meta.CreationTimestamp)
+	}
+	if meta.SelfLink != "" {
+		t.Errorf("SelfLink = %q, want empty", meta.SelfLink)
+	}
+	for _, key := range exportScrubbedAnnotations {
+		if _, ok := meta.Annotations[key]; ok {
+			t.Errorf("annotation %q should have been scrubbed", key)
+		}
+	}
+	if got := meta.Annotations["my-own-annotation"]; got != "keep-me" {
+		t.Errorf("user annotation was scrubbed: got %q", got)
+	}
+	if meta.Name != "my-service" {
+		t.Errorf("Name = %q, should be preserved", meta.Name)
+	}
+}
+
+func TestSanitizeServiceForExportClearsStatus(t *testing.T) {
+	service := &v1alpha1.Service{
+		ObjectMeta: metav1.ObjectMeta{Name: "my-service", UID: types.UID("some-uid")},
+	}
+	service.Status.ObservedGeneration = 5
+
+	out := SanitizeServiceForExport(service)
+
+	if out.Status.ObservedGeneration != 0 {
+		t.Errorf("Status should be cleared, got %+v", out.Status)
+	}
+	if out.UID != "" {
+		t.Errorf("UID should be scrubbed, got %q", out.UID)
+	}
+	if service.UID == "" {
+		t.Errorf("SanitizeServiceForExport should not mutate its input")
+	}
+}
+
+func TestSanitizePodSpecDropsServiceAccountTokenVolume(t *testing.T) {
+	podSpec := corev1.PodSpec{
+		Containers: []corev1.Container{{
+			Name: "user-container",
+			VolumeMounts: []corev1.VolumeMount{
+				{Name: "kube-api-access-xyz", MountPath: "/var/run/secrets/token"},
+				{Name: "user-data", MountPath: "/data"},
+			},
+		}},
+		Volumes: []corev1.Volume{
+			{
+				Name: "kube-api-access-xyz",
+				VolumeSource: corev1.VolumeSource{
+					Projected: &corev1.ProjectedVolumeSource{
+						Sources: []corev1.VolumeProjection{{ServiceAccountToken: &corev1.ServiceAccountTokenProjection{}}},
+					},
+				},
+			},
+			{Name: "user-data"},
+		},
+	}
+
+	sanitizePodSpec(&podSpec)
+
+	if len(podSpec.Volumes) != 1 || podSpec.Volumes[0].Name != "user-data" {
+		t.Errorf("Volumes = %+v, want only user-data", podSpec.Volumes)
+	}
+	mounts := podSpec.Containers[0].VolumeMounts
+	if len(mounts) != 1 || mounts[0].Name != "user-data" {
+		t.Errorf("VolumeMounts = %+v, want only user-data", mounts)
+	}
+}
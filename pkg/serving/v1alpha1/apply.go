@@ -0,0 +1,253 @@
+// Copyright © 2019 The Knative Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v1alpha1
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"time"
+
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/selection"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/strategicpatch"
+
+	"knative.dev/serving/pkg/apis/serving/v1alpha1"
+
+	kn_errors "knative.dev/client/pkg/errors"
+	"knative.dev/client/pkg/serving/v1alpha1/reconcile"
+)
+
+// ApplyOptions controls how ApplyServices/ApplyService converge the cluster
+// with the desired state.
+type ApplyOptions struct {
+	// Prune deletes existing services that are not part of the desired set.
+	// Requires Selector to be set so only services owned by this apply are
+	// ever considered for deletion.
+	Prune bool
+
+	// Selector scopes ListServices when computing what "owned by this apply"
+	// means, both for diffing drift and for Prune. Its equality requirements
+	// are also stamped onto each desired Service before it is created or
+	// patched, so a service this apply just created is guaranteed to be
+	// found by that same scoped List on the next run rather than depending
+	// on the caller to have labeled desired manifests consistently.
+	Selector labels.Selector
+
+	// WaitForReady blocks on WaitForService for each created or updated
+	// service before moving on, up to Timeout.
+	WaitForReady bool
+
+	// Timeout bounds WaitForReady; ignored if WaitForReady is false.
+	Timeout time.Duration
+}
+
+// ApplyResult reports what ApplyServices actually did, keyed by service name.
+type ApplyResult struct {
+	Created   []string
+	Updated   []string
+	Unchanged []string
+	Deleted   []string
+
+	// Errors holds one entry per service name that failed, so a partial
+	// failure doesn't hide the services that did converge successfully.
+	Errors map[string]error
+}
+
+// ApplyService converges a single desired Service with the cluster. It is a
+// convenience wrapper around ApplyServices for the common single-service case.
+func (cl *knServingClient) ApplyService(desired *v1alpha1.Service, opts ApplyOptions) (ApplyResult, error) {
+	return cl.ApplyServices([]*v1alpha1.Service{desired}, opts)
+}
+
+// ApplyServices converges the namespace with the given desired set of
+// Services: missing ones are created, drifted ones are patched with a
+// strategic merge patch, and, if opts.Prune is set, services matched by
+// opts.Selector that fall out of the desired set are deleted. Actions are
+// applied in a deterministic order: creates, then updates, then deletes.
+func (cl *knServingClient) ApplyServices(desiredServices []*v1alpha1.Service, opts ApplyOptions) (ApplyResult, error) {
+	if opts.Prune && opts.Selector == nil {
+		return ApplyResult{}, fmt.Errorf("apply: Prune requires a Selector to scope which services may be deleted")
+	}
+
+	desired := make([]*v1alpha1.Service, len(desiredServices))
+	for idx, svc := range desiredServices {
+		desired[idx] = svc.DeepCopy()
+	}
+	if opts.Selector != nil {
+		if err := stampSelectorLabels(desired, opts.Selector); err != nil {
+			return ApplyResult{}, err
+		}
+	}
+
+	desiredByName := make(map[string]*v1alpha1.Service, len(desired))
+	desiredObjs := make(map[string]interface{}, len(desired))
+	for _, svc := range desired {
+		desiredByName[svc.Name] = svc
+		desiredObjs[svc.Name] = svc
+	}
+
+	var listOpts []ListConfig
+	if opts.Selector != nil {
+		listOpts = append(listOpts, WithLabelSelector(opts.Selector))
+	}
+	actualList, err := cl.ListServices(listOpts...)
+	if err != nil {
+		return ApplyResult{}, err
+	}
+	actualByName := make(map[string]*v1alpha1.Service, len(actualList.Items))
+	actualObjs := make(map[string]interface{}, len(actualList.Items))
+	for idx := range actualList.Items {
+		svc := &actualList.Items[idx]
+		actualByName[svc.Name] = svc
+		actualObjs[svc.Name] = svc
+	}
+
+	plan := reconcile.Diff(desiredObjs, actualObjs, serviceUnchanged)
+
+	result := ApplyResult{Errors: map[string]error{}}
+
+	for _, name := range plan.Creates {
+		if err := cl.CreateService(desiredByName[name]); err != nil {
+			result.Errors[name] = err
+			continue
+		}
+		if err := cl.waitIfRequested(name, opts); err != nil {
+			result.Errors[name] = err
+			continue
+		}
+		result.Created = append(result.Created, name)
+	}
+
+	for _, name := range plan.Updates {
+		if err := cl.patchService(actualByName[name], desiredByName[name]); err != nil {
+			result.Errors[name] = err
+			continue
+		}
+		if err := cl.waitIfRequested(name, opts); err != nil {
+			result.Errors[name] = err
+			continue
+		}
+		result.Updated = append(result.Updated, name)
+	}
+
+	result.Unchanged = plan.Unchanged
+
+	if opts.Prune {
+		for _, name := range plan.Deletes {
+			if err := cl.DeleteService(name); err != nil {
+				result.Errors[name] = err
+				continue
+			}
+			result.Deleted = append(result.Deleted, name)
+		}
+	}
+
+	if len(result.Errors) == 0 {
+		result.Errors = nil
+		return result, nil
+	}
+	return result, fmt.Errorf("apply: %d service(s) failed to converge", len(result.Errors))
+}
+
+// serviceUnchanged reports whether desired is already reflected by actual,
+// i.e. whether ApplyServices has nothing to patch. It compares Spec as well
+// as Labels/Annotations, since a desired manifest that only changes metadata
+// (e.g. retagging a service's labels) must still be classified as drifted.
+func serviceUnchanged(d, a interface{}) bool {
+	desired := d.(*v1alpha1.Service)
+	actual := a.(*v1alpha1.Service)
+	return reflect.DeepEqual(desired.Spec, actual.Spec) &&
+		reflect.DeepEqual(desired.Labels, actual.Labels) &&
+		reflect.DeepEqual(desired.Annotations, actual.Annotations)
+}
+
+// stampSelectorLabels mutates each of the given services in place so it
+// carries every equality label required by selector, failing if selector
+// can't be expressed that way or if a service already carries a conflicting
+// value for one of those labels. This guarantees that a service created or
+// patched by ApplyServices under this Selector will be found by the same
+// selector-scoped List on the next run.
+func stampSelectorLabels(services []*v1alpha1.Service, selector labels.Selector) error {
+	requirements, selectable := selector.Requirements()
+	if !selectable {
+		return fmt.Errorf("apply: Selector must be expressible as equality label requirements")
+	}
+	required := map[string]string{}
+	for _, req := range requirements {
+		if req.Operator() != selection.Equals && req.Operator() != selection.DoubleEquals {
+			return fmt.Errorf("apply: Selector requirement %q must use an equality operator so desired services can be labeled to match it", req)
+		}
+		values := req.Values().List()
+		if len(values) != 1 {
+			return fmt.Errorf("apply: Selector requirement %q must have exactly one value", req)
+		}
+		required[req.Key()] = values[0]
+	}
+	for _, svc := range services {
+		if svc.Labels == nil {
+			svc.Labels = map[string]string{}
+		}
+		for key, value := range required {
+			if existing, ok := svc.Labels[key]; ok && existing != value {
+				return fmt.Errorf("apply: service %q has label %s=%q conflicting with Selector requirement %s=%q",
+					svc.Name, key, existing, key, value)
+			}
+			svc.Labels[key] = value
+		}
+	}
+	return nil
+}
+
+func (cl *knServingClient) waitIfRequested(name string, opts ApplyOptions) error {
+	if !opts.WaitForReady {
+		return nil
+	}
+	return cl.WaitForService(name, opts.Timeout)
+}
+
+// patchService computes a strategic merge patch between the service as last
+// observed on the server (actual) and the desired spec plus Labels/
+// Annotations, and submits it. Spec, Labels and Annotations are set to
+// exactly desired's, so labels and annotations that desired doesn't carry
+// (including other controllers' annotations) are removed; status and other
+// metadata fields are left untouched.
+func (cl *knServingClient) patchService(actual, desired *v1alpha1.Service) error {
+	actualJSON, err := json.Marshal(actual)
+	if err != nil {
+		return err
+	}
+	merged := actual.DeepCopy()
+	merged.Spec = desired.Spec
+	merged.Labels = desired.Labels
+	merged.Annotations = desired.Annotations
+	mergedJSON, err := json.Marshal(merged)
+	if err != nil {
+		return err
+	}
+	patch, err := strategicpatch.CreateTwoWayMergePatch(actualJSON, mergedJSON, &v1alpha1.Service{})
+	if err != nil {
+		return err
+	}
+	if string(patch) == "{}" {
+		return nil
+	}
+	patched, err := cl.client.Services(cl.namespace).Patch(actual.Name, types.StrategicMergePatchType, patch)
+	if err != nil {
+		return kn_errors.GetError(err)
+	}
+	return updateServingGvk(patched)
+}
@@ -0,0 +1,95 @@
+// Copyright © 2019 The Knative Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v1alpha1
+
+import (
+	"testing"
+
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+
+	"knative.dev/serving/pkg/apis/serving/v1alpha1"
+)
+
+func serviceNamed(name string) *v1alpha1.Service {
+	return &v1alpha1.Service{ObjectMeta: v1.ObjectMeta{Name: name}}
+}
+
+func TestStampSelectorLabelsAddsMissingLabels(t *testing.T) {
+	services := []*v1alpha1.Service{serviceNamed("svc-a")}
+	selector := labels.SelectorFromSet(labels.Set{"app": "demo"})
+
+	if err := stampSelectorLabels(services, selector); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := services[0].Labels["app"]; got != "demo" {
+		t.Errorf("Labels[app] = %q, want %q", got, "demo")
+	}
+}
+
+func TestStampSelectorLabelsRejectsConflict(t *testing.T) {
+	svc := serviceNamed("svc-a")
+	svc.Labels = map[string]string{"app": "other"}
+	selector := labels.SelectorFromSet(labels.Set{"app": "demo"})
+
+	if err := stampSelectorLabels([]*v1alpha1.Service{svc}, selector); err == nil {
+		t.Fatal("expected an error for a conflicting label, got nil")
+	}
+}
+
+func TestStampSelectorLabelsRejectsNonEqualitySelector(t *testing.T) {
+	selector, err := labels.Parse("app in (demo, other)")
+	if err != nil {
+		t.Fatalf("failed to parse selector: %v", err)
+	}
+	services := []*v1alpha1.Service{serviceNamed("svc-a")}
+
+	if err := stampSelectorLabels(services, selector); err == nil {
+		t.Fatal("expected an error for a non-equality selector, got nil")
+	}
+}
+
+func TestServiceUnchangedDetectsLabelDrift(t *testing.T) {
+	actual := serviceNamed("svc-a")
+	actual.Labels = map[string]string{"env": "staging"}
+	desired := serviceNamed("svc-a")
+	desired.Labels = map[string]string{"env": "prod"}
+
+	if serviceUnchanged(desired, actual) {
+		t.Error("serviceUnchanged = true, want false for drifted Labels")
+	}
+}
+
+func TestServiceUnchangedDetectsAnnotationDrift(t *testing.T) {
+	actual := serviceNamed("svc-a")
+	actual.Annotations = map[string]string{"note": "old"}
+	desired := serviceNamed("svc-a")
+	desired.Annotations = map[string]string{"note": "new"}
+
+	if serviceUnchanged(desired, actual) {
+		t.Error("serviceUnchanged = true, want false for drifted Annotations")
+	}
+}
+
+func TestServiceUnchangedTrueWhenIdentical(t *testing.T) {
+	actual := serviceNamed("svc-a")
+	actual.Labels = map[string]string{"env": "prod"}
+	desired := serviceNamed("svc-a")
+	desired.Labels = map[string]string{"env": "prod"}
+
+	if !serviceUnchanged(desired, actual) {
+		t.Error("serviceUnchanged = false, want true for identical Spec/Labels/Annotations")
+	}
+}
@@ -15,7 +15,9 @@
 package v1alpha1
 
 import (
+	"context"
 	"fmt"
+	"strconv"
 	"time"
 
 	"k8s.io/apimachinery/pkg/fields"
@@ -79,14 +81,79 @@ type KnServingClient interface {
 
 	// List routes
 	ListRoutes(opts ...ListConfig) (*v1alpha1.RouteList, error)
+
+	// ApplyService converges a single desired Service with the cluster,
+	// creating or patching it as needed
+	ApplyService(desired *v1alpha1.Service, opts ApplyOptions) (ApplyResult, error)
+
+	// ApplyServices converges the namespace with a desired set of Services,
+	// creating missing ones, patching drifted ones and, if requested, pruning
+	// the ones that are no longer part of the desired set
+	ApplyServices(desired []*v1alpha1.Service, opts ApplyOptions) (ApplyResult, error)
+
+	// RollbackService routes traffic for a service back to a previously
+	// created revision, optionally pinning it into a new BYO-named revision
+	RollbackService(name, targetRevision string, opts RollbackOptions) (*v1alpha1.Service, error)
+
+	// ListRevisionHistory lists the revisions belonging to a service, most
+	// recent configurationGeneration first, for presenting rollback candidates
+	ListRevisionHistory(service *v1alpha1.Service, limit int) ([]v1alpha1.Revision, error)
+
+	// ExportService returns a GVK-normalized, server-field-scrubbed copy of a
+	// service, optionally together with the revision history its Route
+	// currently sends traffic to
+	ExportService(name string, mode ExportMode) (*ExportedService, error)
+
+	// ListRevisionsForService lists the revisions of a single service,
+	// without the caller having to hand-build a WithService selector
+	ListRevisionsForService(service string, opts ...ListConfig) (*v1alpha1.RevisionList, error)
+
+	// ListRoutedRevisions lists the revisions of a service that are
+	// currently referenced by its Route's traffic targets
+	ListRoutedRevisions(service string) (*v1alpha1.RevisionList, error)
+
+	// WatchServices streams incremental Added/Modified/Ready/Failed/Deleted
+	// transitions for services matching opts, until ctx is done or the
+	// watch can't be restarted
+	WatchServices(ctx context.Context, opts ...ListConfig) (<-chan ServiceEvent, error)
+
+	// WatchRevisions streams incremental transitions for revisions matching opts
+	WatchRevisions(ctx context.Context, opts ...ListConfig) (<-chan RevisionEvent, error)
+
+	// WatchRoutes streams incremental transitions for routes matching opts
+	WatchRoutes(ctx context.Context, opts ...ListConfig) (<-chan RouteEvent, error)
+
+	// WaitForServices blocks until every named service has become Ready,
+	// failed or run out of the shared timeout, fanning out over WaitForService
+	WaitForServices(names []string, timeout time.Duration) MultiWaitResult
 }
 
 type listConfigCollector struct {
 	// Labels to filter on
 	Labels labels.Set
 
-	// Labels to filter on
+	// Fields to filter on
 	Fields fields.Set
+
+	// LabelSelector, if set, is used verbatim instead of Labels
+	LabelSelector labels.Selector
+
+	// FieldSelector, if set, is used verbatim instead of Fields
+	FieldSelector fields.Selector
+
+	// Limit requests at most this many items per page; 0 means no limit
+	Limit int64
+
+	// Continue resumes a previous paginated List call
+	Continue string
+
+	// ServiceName is set by WithService or WithRevisionOfService, so that WithRoutedOnly
+	// knows which service's Route to consult
+	ServiceName string
+
+	// RoutedOnly, if set, restricts the result to revisions currently
+	// referenced by the named service's Route traffic targets
+	RoutedOnly bool
 }
 
 // Config function for builder pattern
@@ -94,17 +161,38 @@ type ListConfig func(config *listConfigCollector)
 
 type ListConfigs []ListConfig
 
-// add selectors to a list options
-func (opts ListConfigs) toListOptions() v1.ListOptions {
-	listConfig := listConfigCollector{labels.Set{}, fields.Set{}}
+// collect applies every ListConfig in order and returns the resulting collector
+func (opts ListConfigs) collect() listConfigCollector {
+	listConfig := listConfigCollector{Labels: labels.Set{}, Fields: fields.Set{}}
 	for _, f := range opts {
 		f(&listConfig)
 	}
-	options := v1.ListOptions{}
-	if len(listConfig.Fields) > 0 {
+	return listConfig
+}
+
+// add selectors to a list options
+func (opts ListConfigs) toListOptions() v1.ListOptions {
+	return opts.collect().toListOptions()
+}
+
+// toListOptions renders the collected filters as a Kubernetes ListOptions.
+// An explicit LabelSelector/FieldSelector takes precedence over the
+// individual Labels/Fields built up by WithLabel/WithName/etc.
+func (listConfig listConfigCollector) toListOptions() v1.ListOptions {
+	options := v1.ListOptions{
+		Limit:    listConfig.Limit,
+		Continue: listConfig.Continue,
+	}
+	switch {
+	case listConfig.FieldSelector != nil:
+		options.FieldSelector = listConfig.FieldSelector.String()
+	case len(listConfig.Fields) > 0:
 		options.FieldSelector = listConfig.Fields.String()
 	}
-	if len(listConfig.Labels) > 0 {
+	switch {
+	case listConfig.LabelSelector != nil:
+		options.LabelSelector = listConfig.LabelSelector.String()
+	case len(listConfig.Labels) > 0:
 		options.LabelSelector = listConfig.Labels.String()
 	}
 	return options
@@ -121,6 +209,70 @@ func WithName(name string) ListConfig {
 func WithService(service string) ListConfig {
 	return func(lo *listConfigCollector) {
 		lo.Labels[api_serving.ServiceLabelKey] = service
+		lo.ServiceName = service
+	}
+}
+
+// Filter on an arbitrary label, in addition to whatever else has been set
+func WithLabel(key, value string) ListConfig {
+	return func(lo *listConfigCollector) {
+		lo.Labels[key] = value
+	}
+}
+
+// Filter list on an arbitrary label selector, taking precedence over any
+// labels added via WithLabel/WithService/WithRevisionOfService
+func WithLabelSelector(selector labels.Selector) ListConfig {
+	return func(lo *listConfigCollector) {
+		lo.LabelSelector = selector
+	}
+}
+
+// Filter list on an arbitrary field selector, taking precedence over any
+// fields added via WithName
+func WithFieldSelector(selector fields.Selector) ListConfig {
+	return func(lo *listConfigCollector) {
+		lo.FieldSelector = selector
+	}
+}
+
+// Filter revisions on their configuration generation
+func WithConfigurationGeneration(generation int64) ListConfig {
+	return func(lo *listConfigCollector) {
+		lo.Labels[api_serving.ConfigurationGenerationLabelKey] = strconv.FormatInt(generation, 10)
+	}
+}
+
+// Filter revisions belonging to a service, optionally narrowed further with
+// WithConfigurationGeneration
+func WithRevisionOfService(service string) ListConfig {
+	return func(lo *listConfigCollector) {
+		lo.Labels[api_serving.ServiceLabelKey] = service
+		lo.ServiceName = service
+	}
+}
+
+// Restrict the result to revisions currently referenced by the owning
+// service's Route traffic targets. Must be combined with WithService or
+// WithRevisionOfService so the client knows which Route to consult.
+func WithRoutedOnly() ListConfig {
+	return func(lo *listConfigCollector) {
+		lo.RoutedOnly = true
+	}
+}
+
+// Request at most n items per page of a List call
+func WithLimit(n int64) ListConfig {
+	return func(lo *listConfigCollector) {
+		lo.Limit = n
+	}
+}
+
+// Resume a paginated List call from a continuation token previously
+// returned in a list's ListMeta.Continue
+func WithContinue(token string) ListConfig {
+	return func(lo *listConfigCollector) {
+		lo.Continue = token
 	}
 }
 
@@ -305,11 +457,55 @@ func (cl *knServingClient) DeleteRevision(name string) error {
 
 // List revisions
 func (cl *knServingClient) ListRevisions(config ...ListConfig) (*v1alpha1.RevisionList, error) {
-	revisionList, err := cl.client.Revisions(cl.namespace).List(ListConfigs(config).toListOptions())
+	collector := ListConfigs(config).collect()
+	revisionList, err := cl.client.Revisions(cl.namespace).List(collector.toListOptions())
 	if err != nil {
 		return nil, kn_errors.GetError(err)
 	}
-	return updateServingGvkForRevisionList(revisionList)
+	revisionListNew, err := updateServingGvkForRevisionList(revisionList)
+	if err != nil {
+		return nil, err
+	}
+	if collector.RoutedOnly {
+		return cl.filterRoutedRevisions(collector.ServiceName, revisionListNew)
+	}
+	return revisionListNew, nil
+}
+
+// ListRevisionsForService lists the revisions of a single service, without
+// the caller having to hand-build a WithService selector
+func (cl *knServingClient) ListRevisionsForService(service string, opts ...ListConfig) (*v1alpha1.RevisionList, error) {
+	return cl.ListRevisions(append([]ListConfig{WithRevisionOfService(service)}, opts...)...)
+}
+
+// ListRoutedRevisions lists the revisions of a service that are currently
+// referenced by its Route's traffic targets
+func (cl *knServingClient) ListRoutedRevisions(service string) (*v1alpha1.RevisionList, error) {
+	return cl.ListRevisionsForService(service, WithRoutedOnly())
+}
+
+// filterRoutedRevisions narrows a revision list down to the ones currently
+// referenced by serviceName's Route traffic targets.
+func (cl *knServingClient) filterRoutedRevisions(serviceName string, list *v1alpha1.RevisionList) (*v1alpha1.RevisionList, error) {
+	if serviceName == "" {
+		return nil, fmt.Errorf("WithRoutedOnly requires WithService or WithRevisionOfService to know which service's Route to consult")
+	}
+	route, err := cl.GetRoute(serviceName)
+	if err != nil {
+		return nil, err
+	}
+	routed := map[string]bool{}
+	for _, name := range routedRevisionNames(route) {
+		routed[name] = true
+	}
+	filtered := list.DeepCopy()
+	filtered.Items = nil
+	for _, revision := range list.Items {
+		if routed[revision.Name] {
+			filtered.Items = append(filtered.Items, revision)
+		}
+	}
+	return filtered, nil
 }
 
 // Get a route by its unique name
@@ -0,0 +1,137 @@
+// Copyright © 2019 The Knative Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v1alpha1
+
+import (
+	"encoding/json"
+	"fmt"
+
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	"knative.dev/serving/pkg/apis/serving/v1alpha1"
+
+	"knative.dev/client/pkg/serving"
+)
+
+// ExportMode selects what ExportService includes in its result.
+type ExportMode string
+
+const (
+	// ExportModeSingle exports just the current template, GVK-normalized,
+	// with server-populated fields stripped.
+	ExportModeSingle ExportMode = "single"
+
+	// ExportModeRevisionsResources additionally exports every revision
+	// currently referenced by the service's Route traffic targets, as a
+	// Service plus a list of Revisions.
+	ExportModeRevisionsResources ExportMode = "revisions-resources"
+
+	// ExportModeRevisionsKubernetes renders the same routed-revision history
+	// as a plain v1.List containing one Service per routed revision, each
+	// pinned to that revision's spec, for consumers that only understand
+	// stock Kubernetes list semantics.
+	ExportModeRevisionsKubernetes ExportMode = "revisions-kubernetes"
+)
+
+// ExportedService is the result of ExportService. Which fields are
+// populated depends on the requested ExportMode: Revisions is only set for
+// ExportModeRevisionsResources, and List only for ExportModeRevisionsKubernetes.
+type ExportedService struct {
+	Service   *v1alpha1.Service
+	Revisions []v1alpha1.Revision
+	List      *v1.List
+}
+
+// ExportService returns a scrubbed copy of a service suitable for storing as
+// a manifest and replaying through CreateService. In ExportModeSingle only
+// the current template is returned; the Revisions modes additionally walk
+// the service's Route traffic targets and include every revision currently
+// receiving traffic.
+func (cl *knServingClient) ExportService(name string, mode ExportMode) (*ExportedService, error) {
+	service, err := cl.GetService(name)
+	if err != nil {
+		return nil, err
+	}
+	sanitized := serving.SanitizeServiceForExport(service)
+
+	if mode == ExportModeSingle {
+		return &ExportedService{Service: sanitized}, nil
+	}
+
+	route, err := cl.GetRoute(name)
+	if err != nil {
+		return nil, err
+	}
+	revisions := make([]v1alpha1.Revision, 0, len(route.Status.Traffic))
+	for _, revisionName := range routedRevisionNames(route) {
+		revision, err := cl.GetRevision(revisionName)
+		if err != nil {
+			return nil, err
+		}
+		revisions = append(revisions, *serving.SanitizeRevisionForExport(revision))
+	}
+
+	switch mode {
+	case ExportModeRevisionsResources:
+		return &ExportedService{Service: sanitized, Revisions: revisions}, nil
+	case ExportModeRevisionsKubernetes:
+		list, err := servicePerRevisionList(sanitized, revisions)
+		if err != nil {
+			return nil, err
+		}
+		return &ExportedService{List: list}, nil
+	default:
+		return nil, fmt.Errorf("export: unknown mode %q", mode)
+	}
+}
+
+// routedRevisionNames returns the distinct revision names a Route's traffic
+// targets currently point at, in the order they first appear.
+func routedRevisionNames(route *v1alpha1.Route) []string {
+	seen := map[string]bool{}
+	var names []string
+	for _, target := range route.Status.Traffic {
+		if target.RevisionName == "" || seen[target.RevisionName] {
+			continue
+		}
+		seen[target.RevisionName] = true
+		names = append(names, target.RevisionName)
+	}
+	return names
+}
+
+// servicePerRevisionList renders one distinctly-named Service per routed
+// revision, each with its template pinned to that revision's spec, as a
+// plain v1.List.
+func servicePerRevisionList(service *v1alpha1.Service, revisions []v1alpha1.Revision) (*v1.List, error) {
+	list := &v1.List{}
+	for _, revision := range revisions {
+		pinned := service.DeepCopy()
+		pinned.Name = fmt.Sprintf("%s-%s", service.Name, revision.Name)
+		template, err := serving.RevisionTemplateOfService(pinned)
+		if err != nil {
+			return nil, err
+		}
+		template.Spec = revision.Spec
+		template.Name = revision.Name
+		raw, err := json.Marshal(pinned)
+		if err != nil {
+			return nil, err
+		}
+		list.Items = append(list.Items, runtime.RawExtension{Raw: raw})
+	}
+	return list, nil
+}
@@ -0,0 +1,29 @@
+// Copyright © 2019 The Knative Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v1alpha1
+
+import (
+	"k8s.io/apimachinery/pkg/runtime"
+
+	"knative.dev/serving/pkg/client/clientset/versioned/fake"
+)
+
+// newTestClient builds a knServingClient backed by a fake clientset seeded
+// with objects, for tests that need to exercise real client methods rather
+// than just their pure helpers.
+func newTestClient(objects ...runtime.Object) *knServingClient {
+	clientset := fake.NewSimpleClientset(objects...)
+	return &knServingClient{client: clientset.ServingV1alpha1(), namespace: "default"}
+}
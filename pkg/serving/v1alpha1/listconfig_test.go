@@ -0,0 +1,133 @@
+// Copyright © 2019 The Knative Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v1alpha1
+
+import (
+	"testing"
+
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/labels"
+
+	"knative.dev/serving/pkg/apis/serving/v1alpha1"
+)
+
+func TestWithNameSetsFieldSelector(t *testing.T) {
+	options := ListConfigs{WithName("my-service")}.toListOptions()
+	if options.FieldSelector != "metadata.name=my-service" {
+		t.Errorf("FieldSelector = %q, want metadata.name=my-service", options.FieldSelector)
+	}
+}
+
+func TestWithServiceAndWithLabelCombine(t *testing.T) {
+	options := ListConfigs{WithService("my-service"), WithLabel("env", "prod")}.toListOptions()
+	selector, err := labels.Parse(options.LabelSelector)
+	if err != nil {
+		t.Fatalf("failed to parse LabelSelector %q: %v", options.LabelSelector, err)
+	}
+	if !selector.Matches(labels.Set{"serving.knative.dev/service": "my-service", "env": "prod"}) {
+		t.Errorf("LabelSelector %q does not match expected labels", options.LabelSelector)
+	}
+}
+
+func TestWithLabelSelectorTakesPrecedenceOverWithLabel(t *testing.T) {
+	explicit := labels.SelectorFromSet(labels.Set{"only": "this"})
+	options := ListConfigs{WithLabel("env", "prod"), WithLabelSelector(explicit)}.toListOptions()
+	if options.LabelSelector != explicit.String() {
+		t.Errorf("LabelSelector = %q, want explicit selector %q to win", options.LabelSelector, explicit.String())
+	}
+}
+
+func TestWithFieldSelectorTakesPrecedenceOverWithName(t *testing.T) {
+	explicit := fields.SelectorFromSet(fields.Set{"status.phase": "Running"})
+	options := ListConfigs{WithName("ignored"), WithFieldSelector(explicit)}.toListOptions()
+	if options.FieldSelector != explicit.String() {
+		t.Errorf("FieldSelector = %q, want explicit selector %q to win", options.FieldSelector, explicit.String())
+	}
+}
+
+func TestWithConfigurationGeneration(t *testing.T) {
+	options := ListConfigs{WithRevisionOfService("my-service"), WithConfigurationGeneration(3)}.toListOptions()
+	selector, err := labels.Parse(options.LabelSelector)
+	if err != nil {
+		t.Fatalf("failed to parse LabelSelector %q: %v", options.LabelSelector, err)
+	}
+	if !selector.Matches(labels.Set{
+		"serving.knative.dev/service":               "my-service",
+		"serving.knative.dev/configurationGeneration": "3",
+	}) {
+		t.Errorf("LabelSelector %q does not match expected labels", options.LabelSelector)
+	}
+}
+
+func TestWithLimitAndContinue(t *testing.T) {
+	options := ListConfigs{WithLimit(50), WithContinue("some-token")}.toListOptions()
+	if options.Limit != 50 {
+		t.Errorf("Limit = %d, want 50", options.Limit)
+	}
+	if options.Continue != "some-token" {
+		t.Errorf("Continue = %q, want some-token", options.Continue)
+	}
+}
+
+func TestCollectSetsServiceNameForRoutedOnly(t *testing.T) {
+	collector := ListConfigs{WithRevisionOfService("my-service"), WithRoutedOnly()}.collect()
+	if collector.ServiceName != "my-service" {
+		t.Errorf("ServiceName = %q, want my-service", collector.ServiceName)
+	}
+	if !collector.RoutedOnly {
+		t.Error("RoutedOnly = false, want true")
+	}
+}
+
+func TestListRevisionsForServiceFiltersByServiceLabel(t *testing.T) {
+	wanted := revisionWithServiceAndGeneration("my-svc-00001", "my-svc", "1")
+	other := revisionWithServiceAndGeneration("other-svc-00001", "other-svc", "1")
+	cl := newTestClient(&wanted, &other)
+
+	list, err := cl.ListRevisionsForService("my-svc")
+	if err != nil {
+		t.Fatalf("ListRevisionsForService() error = %v", err)
+	}
+	if len(list.Items) != 1 || list.Items[0].Name != wanted.Name {
+		t.Errorf("Items = %v, want only %q", list.Items, wanted.Name)
+	}
+}
+
+func TestListRoutedRevisionsFiltersToTrafficTargets(t *testing.T) {
+	routed := revisionWithServiceAndGeneration("my-svc-00002", "my-svc", "2")
+	unrouted := revisionWithServiceAndGeneration("my-svc-00001", "my-svc", "1")
+	route := &v1alpha1.Route{ObjectMeta: v1.ObjectMeta{Name: "my-svc", Namespace: "default"}}
+	target := v1alpha1.TrafficTarget{}
+	target.RevisionName = routed.Name
+	target.Percent = 100
+	route.Status.Traffic = []v1alpha1.TrafficTarget{target}
+	cl := newTestClient(&routed, &unrouted, route)
+
+	list, err := cl.ListRoutedRevisions("my-svc")
+	if err != nil {
+		t.Fatalf("ListRoutedRevisions() error = %v", err)
+	}
+	if len(list.Items) != 1 || list.Items[0].Name != routed.Name {
+		t.Errorf("Items = %v, want only %q", list.Items, routed.Name)
+	}
+}
+
+func TestFilterRoutedRevisionsRequiresServiceName(t *testing.T) {
+	cl := newTestClient()
+	if _, err := cl.filterRoutedRevisions("", &v1alpha1.RevisionList{}); err == nil {
+		t.Fatal("expected an error when serviceName is empty, got nil")
+	}
+}
@@ -0,0 +1,70 @@
+// Copyright © 2019 The Knative Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package reconcile provides a small, generic declarative-apply helper:
+// given a desired set of named objects and the actual set currently on the
+// server, it computes the ordered set of creates/updates/deletes needed to
+// converge the two. It knows nothing about Kubernetes or Knative types; the
+// caller supplies the equality check and does the actual API calls.
+package reconcile
+
+import "sort"
+
+// EqualFunc reports whether desired and actual are already in sync, i.e.
+// whether no update is necessary to bring actual in line with desired.
+type EqualFunc func(desired, actual interface{}) bool
+
+// Plan is the ordered, deterministic set of actions required to converge
+// actual state to desired state. Names within each slice are sorted so that
+// applying a Plan is reproducible across runs.
+type Plan struct {
+	// Creates are names present in desired but not in actual.
+	Creates []string
+
+	// Updates are names present in both, where actual has drifted from desired.
+	Updates []string
+
+	// Unchanged are names present in both, already matching.
+	Unchanged []string
+
+	// Deletes are names present in actual but not in desired.
+	Deletes []string
+}
+
+// Diff computes a Plan to converge actual to desired, keyed by name. equal is
+// used to decide whether a name present in both maps counts as drifted.
+func Diff(desired, actual map[string]interface{}, equal EqualFunc) Plan {
+	plan := Plan{}
+	for name, desiredObj := range desired {
+		actualObj, ok := actual[name]
+		switch {
+		case !ok:
+			plan.Creates = append(plan.Creates, name)
+		case !equal(desiredObj, actualObj):
+			plan.Updates = append(plan.Updates, name)
+		default:
+			plan.Unchanged = append(plan.Unchanged, name)
+		}
+	}
+	for name := range actual {
+		if _, ok := desired[name]; !ok {
+			plan.Deletes = append(plan.Deletes, name)
+		}
+	}
+	sort.Strings(plan.Creates)
+	sort.Strings(plan.Updates)
+	sort.Strings(plan.Unchanged)
+	sort.Strings(plan.Deletes)
+	return plan
+}
@@ -0,0 +1,71 @@
+// Copyright © 2019 The Knative Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package reconcile
+
+import (
+	"reflect"
+	"testing"
+)
+
+func equalStrings(d, a interface{}) bool {
+	return d.(string) == a.(string)
+}
+
+func TestDiff(t *testing.T) {
+	desired := map[string]interface{}{
+		"create-me":  "v2",
+		"update-me":  "v2",
+		"unchanged":  "v1",
+	}
+	actual := map[string]interface{}{
+		"update-me": "v1",
+		"unchanged": "v1",
+		"delete-me": "v1",
+	}
+
+	plan := Diff(desired, actual, equalStrings)
+
+	if got, want := plan.Creates, []string{"create-me"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Creates = %v, want %v", got, want)
+	}
+	if got, want := plan.Updates, []string{"update-me"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Updates = %v, want %v", got, want)
+	}
+	if got, want := plan.Unchanged, []string{"unchanged"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Unchanged = %v, want %v", got, want)
+	}
+	if got, want := plan.Deletes, []string{"delete-me"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Deletes = %v, want %v", got, want)
+	}
+}
+
+func TestDiffIsSortedAndDeterministic(t *testing.T) {
+	desired := map[string]interface{}{"b": "1", "a": "1", "c": "1"}
+	actual := map[string]interface{}{}
+
+	plan := Diff(desired, actual, equalStrings)
+
+	want := []string{"a", "b", "c"}
+	if !reflect.DeepEqual(plan.Creates, want) {
+		t.Errorf("Creates = %v, want sorted %v", plan.Creates, want)
+	}
+}
+
+func TestDiffEmpty(t *testing.T) {
+	plan := Diff(map[string]interface{}{}, map[string]interface{}{}, equalStrings)
+	if len(plan.Creates) != 0 || len(plan.Updates) != 0 || len(plan.Unchanged) != 0 || len(plan.Deletes) != 0 {
+		t.Errorf("expected an empty plan, got %+v", plan)
+	}
+}
@@ -0,0 +1,139 @@
+// Copyright © 2019 The Knative Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v1alpha1
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+
+	"k8s.io/apimachinery/pkg/util/rand"
+
+	api_serving "knative.dev/serving/pkg/apis/serving"
+	"knative.dev/serving/pkg/apis/serving/v1alpha1"
+
+	"knative.dev/client/pkg/serving"
+)
+
+// RollbackOptions controls how RollbackService re-routes traffic to a
+// previously created revision.
+type RollbackOptions struct {
+	// Percent is the traffic share to assign to targetRevision. Defaults to
+	// 100, i.e. a full rollback away from whatever is currently serving.
+	Percent int
+
+	// PinTemplate additionally copies the target revision's PodSpec back
+	// into service.Spec.Template under a fresh BYO revision name, so a new
+	// revision is materialized identical to the old one rather than only
+	// re-routing traffic to it.
+	PinTemplate bool
+
+	// DryRun returns the resulting Service without submitting the change.
+	DryRun bool
+}
+
+// RollbackService routes opts.Percent (100 by default) of a service's
+// traffic to targetRevision. The revision must already exist, carry the
+// service's label, and carry a configuration label naming the service's
+// current Configuration; otherwise an error is returned. These checks
+// compare label values only, so a revision whose labels name this service
+// and its Configuration is accepted even if an earlier service of the same
+// name created it. With opts.DryRun set, the
+// resulting Service is computed and returned but never submitted to the API
+// server.
+//
+// There is deliberately no cross-schema-version check here: both service
+// and revision are always GVK-normalized to v1alpha1.SchemeGroupVersion by
+// updateServingGvk before we ever see them (this client only ever speaks
+// v1alpha1), so a comparison of their APIVersion fields would never be able
+// to fire and would only be misleading dead code. If this client grows
+// support for more than one serving API schema, that's where a real check
+// belongs.
+func (cl *knServingClient) RollbackService(name, targetRevision string, opts RollbackOptions) (*v1alpha1.Service, error) {
+	percent := opts.Percent
+	if percent == 0 {
+		percent = 100
+	}
+
+	service, err := cl.GetService(name)
+	if err != nil {
+		return nil, err
+	}
+	revision, err := cl.GetRevision(targetRevision)
+	if err != nil {
+		return nil, err
+	}
+	if revision.Labels[api_serving.ServiceLabelKey] != service.Name {
+		return nil, fmt.Errorf("revision %q does not belong to service %q", targetRevision, name)
+	}
+	configuration, err := cl.GetConfiguration(service.Name)
+	if err != nil {
+		return nil, err
+	}
+	if revision.Labels[api_serving.ConfigurationLabelKey] != configuration.Name {
+		return nil, fmt.Errorf("revision %q does not belong to the current configuration %q of service %q",
+			targetRevision, configuration.Name, name)
+	}
+
+	updated := service.DeepCopy()
+	target := v1alpha1.TrafficTarget{}
+	target.RevisionName = targetRevision
+	target.Percent = percent
+	updated.Spec.Traffic = []v1alpha1.TrafficTarget{target}
+
+	if opts.PinTemplate {
+		template, err := serving.RevisionTemplateOfService(updated)
+		if err != nil {
+			return nil, err
+		}
+		template.Spec.PodSpec = revision.Spec.PodSpec
+		template.Name = fmt.Sprintf("%s-%s", service.Name, rand.String(5))
+	}
+
+	if opts.DryRun {
+		return updated, nil
+	}
+	if err := cl.UpdateService(updated); err != nil {
+		return nil, err
+	}
+	return updated, nil
+}
+
+// ListRevisionHistory lists the revisions belonging to a service, ordered by
+// serving.knative.dev/configurationGeneration descending (most recent
+// first), so callers can present rollback candidates. limit caps the number
+// of revisions returned; limit <= 0 means no cap.
+func (cl *knServingClient) ListRevisionHistory(service *v1alpha1.Service, limit int) ([]v1alpha1.Revision, error) {
+	list, err := cl.ListRevisions(WithService(service.Name))
+	if err != nil {
+		return nil, err
+	}
+	revisions := list.Items
+	sort.Slice(revisions, func(i, j int) bool {
+		return configurationGeneration(&revisions[i]) > configurationGeneration(&revisions[j])
+	})
+	if limit > 0 && len(revisions) > limit {
+		revisions = revisions[:limit]
+	}
+	return revisions, nil
+}
+
+func configurationGeneration(revision *v1alpha1.Revision) int64 {
+	gen, err := strconv.ParseInt(revision.Labels[api_serving.ConfigurationGenerationLabelKey], 10, 64)
+	if err != nil {
+		return 0
+	}
+	return gen
+}
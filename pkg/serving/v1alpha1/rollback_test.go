@@ -0,0 +1,234 @@
+// Copyright © 2019 The Knative Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v1alpha1
+
+import (
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	api_serving "knative.dev/serving/pkg/apis/serving"
+	"knative.dev/serving/pkg/apis/serving/v1alpha1"
+
+	"knative.dev/client/pkg/serving"
+)
+
+func revisionWithGeneration(name, generation string) v1alpha1.Revision {
+	return v1alpha1.Revision{
+		ObjectMeta: v1.ObjectMeta{
+			Name:      name,
+			Namespace: "default",
+			Labels:    map[string]string{api_serving.ConfigurationGenerationLabelKey: generation},
+		},
+	}
+}
+
+func revisionWithServiceAndGeneration(name, service, generation string) v1alpha1.Revision {
+	revision := revisionWithGeneration(name, generation)
+	revision.Labels[api_serving.ServiceLabelKey] = service
+	return revision
+}
+
+func revisionOwnedBy(name, service, configuration string) v1alpha1.Revision {
+	return v1alpha1.Revision{
+		ObjectMeta: v1.ObjectMeta{
+			Name:      name,
+			Namespace: "default",
+			Labels: map[string]string{
+				api_serving.ServiceLabelKey:       service,
+				api_serving.ConfigurationLabelKey: configuration,
+			},
+		},
+	}
+}
+
+func namespacedServiceNamed(name string) *v1alpha1.Service {
+	svc := serviceNamed(name)
+	svc.Namespace = "default"
+	return svc
+}
+
+func configurationNamed(name string) *v1alpha1.Configuration {
+	return &v1alpha1.Configuration{ObjectMeta: v1.ObjectMeta{Name: name, Namespace: "default"}}
+}
+
+func TestConfigurationGeneration(t *testing.T) {
+	cases := []struct {
+		name       string
+		generation string
+		want       int64
+	}{
+		{"valid", "3", 3},
+		{"missing", "", 0},
+		{"not-a-number", "not-a-number", 0},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			revision := revisionWithGeneration("rev", c.generation)
+			if got := configurationGeneration(&revision); got != c.want {
+				t.Errorf("configurationGeneration() = %d, want %d", got, c.want)
+			}
+		})
+	}
+}
+
+func TestConfigurationGenerationOrdering(t *testing.T) {
+	revisions := []v1alpha1.Revision{
+		revisionWithGeneration("rev-1", "1"),
+		revisionWithGeneration("rev-3", "3"),
+		revisionWithGeneration("rev-2", "2"),
+	}
+
+	sort.Slice(revisions, func(i, j int) bool {
+		return configurationGeneration(&revisions[i]) > configurationGeneration(&revisions[j])
+	})
+
+	var names []string
+	for _, revision := range revisions {
+		names = append(names, revision.Name)
+	}
+	want := []string{"rev-3", "rev-2", "rev-1"}
+	for i := range want {
+		if names[i] != want[i] {
+			t.Errorf("ordering = %v, want %v", names, want)
+			break
+		}
+	}
+}
+
+func TestRollbackServiceSubmitsTrafficUpdate(t *testing.T) {
+	revision := revisionOwnedBy("my-svc-00001", "my-svc", "my-svc")
+	service := namespacedServiceNamed("my-svc")
+	configuration := configurationNamed("my-svc")
+	cl := newTestClient(service, configuration, &revision)
+
+	if _, err := cl.RollbackService("my-svc", revision.Name, RollbackOptions{Percent: 50}); err != nil {
+		t.Fatalf("RollbackService() error = %v", err)
+	}
+
+	persisted, err := cl.GetService("my-svc")
+	if err != nil {
+		t.Fatalf("GetService() error = %v", err)
+	}
+	if len(persisted.Spec.Traffic) != 1 || persisted.Spec.Traffic[0].RevisionName != revision.Name || persisted.Spec.Traffic[0].Percent != 50 {
+		t.Errorf("Spec.Traffic = %+v, want a single 50%% target at %q", persisted.Spec.Traffic, revision.Name)
+	}
+}
+
+func TestRollbackServiceDefaultsToFullTraffic(t *testing.T) {
+	revision := revisionOwnedBy("my-svc-00001", "my-svc", "my-svc")
+	service := namespacedServiceNamed("my-svc")
+	configuration := configurationNamed("my-svc")
+	cl := newTestClient(service, configuration, &revision)
+
+	updated, err := cl.RollbackService("my-svc", revision.Name, RollbackOptions{})
+	if err != nil {
+		t.Fatalf("RollbackService() error = %v", err)
+	}
+	if len(updated.Spec.Traffic) != 1 || updated.Spec.Traffic[0].Percent != 100 {
+		t.Errorf("Spec.Traffic = %+v, want a single 100%% target", updated.Spec.Traffic)
+	}
+}
+
+func TestRollbackServiceDryRunDoesNotSubmit(t *testing.T) {
+	revision := revisionOwnedBy("my-svc-00001", "my-svc", "my-svc")
+	service := namespacedServiceNamed("my-svc")
+	configuration := configurationNamed("my-svc")
+	cl := newTestClient(service, configuration, &revision)
+
+	if _, err := cl.RollbackService("my-svc", revision.Name, RollbackOptions{DryRun: true}); err != nil {
+		t.Fatalf("RollbackService() error = %v", err)
+	}
+
+	persisted, err := cl.GetService("my-svc")
+	if err != nil {
+		t.Fatalf("GetService() error = %v", err)
+	}
+	if len(persisted.Spec.Traffic) != 0 {
+		t.Errorf("DryRun should not submit the update, but server now has Traffic = %+v", persisted.Spec.Traffic)
+	}
+}
+
+func TestRollbackServicePinTemplateRewritesBYOName(t *testing.T) {
+	revision := revisionOwnedBy("my-svc-00002", "my-svc", "my-svc")
+	revision.Spec.PodSpec = corev1.PodSpec{Containers: []corev1.Container{{Image: "gcr.io/demo/v2"}}}
+	service := namespacedServiceNamed("my-svc")
+	service.Spec.Template = &v1alpha1.RevisionTemplateSpec{}
+	configuration := configurationNamed("my-svc")
+	cl := newTestClient(service, configuration, &revision)
+
+	updated, err := cl.RollbackService("my-svc", revision.Name, RollbackOptions{PinTemplate: true, DryRun: true})
+	if err != nil {
+		t.Fatalf("RollbackService() error = %v", err)
+	}
+
+	template, err := serving.RevisionTemplateOfService(updated)
+	if err != nil {
+		t.Fatalf("RevisionTemplateOfService() error = %v", err)
+	}
+	if !reflect.DeepEqual(template.Spec.PodSpec, revision.Spec.PodSpec) {
+		t.Errorf("template PodSpec = %+v, want %+v", template.Spec.PodSpec, revision.Spec.PodSpec)
+	}
+	if !strings.HasPrefix(template.Name, "my-svc-") || template.Name == revision.Name {
+		t.Errorf("template.Name = %q, want a fresh BYO name prefixed with %q", template.Name, "my-svc-")
+	}
+}
+
+func TestRollbackServiceRejectsRevisionFromAnotherService(t *testing.T) {
+	revision := revisionOwnedBy("other-svc-00001", "other-svc", "other-svc")
+	service := namespacedServiceNamed("my-svc")
+	configuration := configurationNamed("my-svc")
+	cl := newTestClient(service, configuration, &revision)
+
+	if _, err := cl.RollbackService("my-svc", revision.Name, RollbackOptions{}); err == nil {
+		t.Fatal("expected an error for a revision belonging to another service, got nil")
+	}
+}
+
+func TestRollbackServiceRejectsRevisionFromStaleConfiguration(t *testing.T) {
+	// The revision carries the service's label, but its configuration label
+	// names a Configuration other than the service's current one.
+	revision := revisionOwnedBy("my-svc-00001", "my-svc", "my-svc-stale-generation")
+	service := namespacedServiceNamed("my-svc")
+	configuration := configurationNamed("my-svc")
+	cl := newTestClient(service, configuration, &revision)
+
+	if _, err := cl.RollbackService("my-svc", revision.Name, RollbackOptions{}); err == nil {
+		t.Fatal("expected an error for a revision belonging to a stale configuration, got nil")
+	}
+}
+
+func TestListRevisionHistoryOrdersAndTruncates(t *testing.T) {
+	rev1 := revisionWithServiceAndGeneration("my-svc-00001", "my-svc", "1")
+	rev2 := revisionWithServiceAndGeneration("my-svc-00002", "my-svc", "2")
+	rev3 := revisionWithServiceAndGeneration("my-svc-00003", "my-svc", "3")
+	service := namespacedServiceNamed("my-svc")
+	cl := newTestClient(service, &rev1, &rev2, &rev3)
+
+	history, err := cl.ListRevisionHistory(service, 2)
+	if err != nil {
+		t.Fatalf("ListRevisionHistory() error = %v", err)
+	}
+	if len(history) != 2 {
+		t.Fatalf("len(history) = %d, want 2", len(history))
+	}
+	if history[0].Name != "my-svc-00003" || history[1].Name != "my-svc-00002" {
+		t.Errorf("history = [%s %s], want [my-svc-00003 my-svc-00002]", history[0].Name, history[1].Name)
+	}
+}
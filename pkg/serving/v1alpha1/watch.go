@@ -0,0 +1,279 @@
+// Copyright © 2019 The Knative Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v1alpha1
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/watch"
+	"knative.dev/pkg/apis"
+
+	"knative.dev/serving/pkg/apis/serving/v1alpha1"
+
+	kn_errors "knative.dev/client/pkg/errors"
+)
+
+// EventType is the kind of transition a Watch* channel reports.
+type EventType string
+
+const (
+	EventAdded    EventType = "Added"
+	EventModified EventType = "Modified"
+	EventDeleted  EventType = "Deleted"
+	EventReady    EventType = "Ready"
+	EventFailed   EventType = "Failed"
+)
+
+// ServiceEvent is a single observed transition of a Service.
+type ServiceEvent struct {
+	Type       EventType
+	Object     *v1alpha1.Service
+	Conditions apis.Conditions
+}
+
+// RevisionEvent is a single observed transition of a Revision.
+type RevisionEvent struct {
+	Type       EventType
+	Object     *v1alpha1.Revision
+	Conditions apis.Conditions
+}
+
+// RouteEvent is a single observed transition of a Route.
+type RouteEvent struct {
+	Type       EventType
+	Object     *v1alpha1.Route
+	Conditions apis.Conditions
+}
+
+// MultiWaitResult reports the outcome of waiting on a batch of services.
+type MultiWaitResult struct {
+	Ready  []string
+	Failed map[string]error
+}
+
+// WatchServices streams incremental transitions for services matching opts.
+// Unlike WaitForService it doesn't stop at a terminal outcome: it keeps
+// publishing Added/Modified/Ready/Failed/Deleted events, restarting the
+// underlying watch with a resourceVersion bookmark if the server closes it,
+// until ctx is done. If restarting the watch fails, the channel is closed.
+func (cl *knServingClient) WatchServices(ctx context.Context, opts ...ListConfig) (<-chan ServiceEvent, error) {
+	listOptions := ListConfigs(opts).toListOptions()
+	watcher, err := cl.client.Services(cl.namespace).Watch(listOptions)
+	if err != nil {
+		return nil, kn_errors.GetError(err)
+	}
+	events := make(chan ServiceEvent)
+	go func() {
+		defer close(events)
+		seen := map[string]*apis.Condition{}
+		for {
+			select {
+			case <-ctx.Done():
+				watcher.Stop()
+				return
+			case rawEvent, ok := <-watcher.ResultChan():
+				if !ok {
+					watcher, err = cl.client.Services(cl.namespace).Watch(listOptions)
+					if err != nil {
+						return
+					}
+					continue
+				}
+				service, ok := rawEvent.Object.(*v1alpha1.Service)
+				if !ok {
+					continue
+				}
+				if err := updateServingGvk(service); err != nil {
+					continue
+				}
+				listOptions.ResourceVersion = service.ResourceVersion
+				conditions := apis.Conditions(service.Status.Conditions)
+				eventType, ready := classifyEvent(rawEvent.Type, conditions, seen[service.Name])
+				if eventType == "" {
+					continue
+				}
+				seen[service.Name] = ready
+				select {
+				case events <- ServiceEvent{Type: eventType, Object: service, Conditions: conditions}:
+				case <-ctx.Done():
+					watcher.Stop()
+					return
+				}
+			}
+		}
+	}()
+	return events, nil
+}
+
+// WatchRevisions streams incremental transitions for revisions matching opts.
+func (cl *knServingClient) WatchRevisions(ctx context.Context, opts ...ListConfig) (<-chan RevisionEvent, error) {
+	listOptions := ListConfigs(opts).toListOptions()
+	watcher, err := cl.client.Revisions(cl.namespace).Watch(listOptions)
+	if err != nil {
+		return nil, kn_errors.GetError(err)
+	}
+	events := make(chan RevisionEvent)
+	go func() {
+		defer close(events)
+		seen := map[string]*apis.Condition{}
+		for {
+			select {
+			case <-ctx.Done():
+				watcher.Stop()
+				return
+			case rawEvent, ok := <-watcher.ResultChan():
+				if !ok {
+					watcher, err = cl.client.Revisions(cl.namespace).Watch(listOptions)
+					if err != nil {
+						return
+					}
+					continue
+				}
+				revision, ok := rawEvent.Object.(*v1alpha1.Revision)
+				if !ok {
+					continue
+				}
+				if err := updateServingGvk(revision); err != nil {
+					continue
+				}
+				listOptions.ResourceVersion = revision.ResourceVersion
+				conditions := apis.Conditions(revision.Status.Conditions)
+				eventType, ready := classifyEvent(rawEvent.Type, conditions, seen[revision.Name])
+				if eventType == "" {
+					continue
+				}
+				seen[revision.Name] = ready
+				select {
+				case events <- RevisionEvent{Type: eventType, Object: revision, Conditions: conditions}:
+				case <-ctx.Done():
+					watcher.Stop()
+					return
+				}
+			}
+		}
+	}()
+	return events, nil
+}
+
+// WatchRoutes streams incremental transitions for routes matching opts.
+func (cl *knServingClient) WatchRoutes(ctx context.Context, opts ...ListConfig) (<-chan RouteEvent, error) {
+	listOptions := ListConfigs(opts).toListOptions()
+	watcher, err := cl.client.Routes(cl.namespace).Watch(listOptions)
+	if err != nil {
+		return nil, kn_errors.GetError(err)
+	}
+	events := make(chan RouteEvent)
+	go func() {
+		defer close(events)
+		seen := map[string]*apis.Condition{}
+		for {
+			select {
+			case <-ctx.Done():
+				watcher.Stop()
+				return
+			case rawEvent, ok := <-watcher.ResultChan():
+				if !ok {
+					watcher, err = cl.client.Routes(cl.namespace).Watch(listOptions)
+					if err != nil {
+						return
+					}
+					continue
+				}
+				route, ok := rawEvent.Object.(*v1alpha1.Route)
+				if !ok {
+					continue
+				}
+				if err := updateServingGvk(route); err != nil {
+					continue
+				}
+				listOptions.ResourceVersion = route.ResourceVersion
+				conditions := apis.Conditions(route.Status.Conditions)
+				eventType, ready := classifyEvent(rawEvent.Type, conditions, seen[route.Name])
+				if eventType == "" {
+					continue
+				}
+				seen[route.Name] = ready
+				select {
+				case events <- RouteEvent{Type: eventType, Object: route, Conditions: conditions}:
+				case <-ctx.Done():
+					watcher.Stop()
+					return
+				}
+			}
+		}
+	}()
+	return events, nil
+}
+
+// classifyEvent turns a raw watch event plus the object's current Ready
+// condition into one of our EventTypes, comparing against the Ready
+// condition last published for that object (previous). It returns an empty
+// EventType to signal that this transition should be coalesced away, i.e.
+// Ready status and reason are unchanged since the last published event.
+func classifyEvent(watchType watch.EventType, conditions apis.Conditions, previous *apis.Condition) (EventType, *apis.Condition) {
+	ready := conditions.GetCondition(apis.ConditionReady)
+	switch watchType {
+	case watch.Added:
+		return EventAdded, ready
+	case watch.Deleted:
+		return EventDeleted, ready
+	}
+	if ready == nil {
+		return EventModified, nil
+	}
+	if previous != nil && previous.Status == ready.Status && previous.Reason == ready.Reason {
+		return "", ready
+	}
+	switch ready.Status {
+	case corev1.ConditionTrue:
+		return EventReady, ready
+	case corev1.ConditionFalse:
+		return EventFailed, ready
+	default:
+		return EventModified, ready
+	}
+}
+
+// WaitForServices blocks until every named service has become Ready, failed
+// or run out of the shared timeout, fanning out over the same WaitForService watch
+// machinery so a script or reconciler driving many services (e.g. after
+// ApplyServices) can block on the whole batch instead of serializing one
+// WaitForService call after another.
+func (cl *knServingClient) WaitForServices(names []string, timeout time.Duration) MultiWaitResult {
+	result := MultiWaitResult{Failed: map[string]error{}}
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for _, name := range names {
+		wg.Add(1)
+		go func(name string) {
+			defer wg.Done()
+			err := cl.WaitForService(name, timeout)
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				result.Failed[name] = err
+				return
+			}
+			result.Ready = append(result.Ready, name)
+		}(name)
+	}
+	wg.Wait()
+	sort.Strings(result.Ready)
+	return result
+}
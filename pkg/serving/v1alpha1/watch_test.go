@@ -0,0 +1,168 @@
+// Copyright © 2019 The Knative Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v1alpha1
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/watch"
+	"knative.dev/pkg/apis"
+)
+
+func readyCondition(status corev1.ConditionStatus, reason string) apis.Conditions {
+	return apis.Conditions{{Type: apis.ConditionReady, Status: status, Reason: reason}}
+}
+
+func TestClassifyEventAdded(t *testing.T) {
+	eventType, ready := classifyEvent(watch.Added, readyCondition(corev1.ConditionUnknown, "Deploying"), nil)
+	if eventType != EventAdded {
+		t.Errorf("eventType = %v, want %v", eventType, EventAdded)
+	}
+	if ready == nil {
+		t.Error("ready condition should be returned for Added events")
+	}
+}
+
+func TestClassifyEventDeleted(t *testing.T) {
+	eventType, _ := classifyEvent(watch.Deleted, readyCondition(corev1.ConditionTrue, "Ready"), nil)
+	if eventType != EventDeleted {
+		t.Errorf("eventType = %v, want %v", eventType, EventDeleted)
+	}
+}
+
+func TestClassifyEventModifiedBecomesReady(t *testing.T) {
+	previous := readyCondition(corev1.ConditionUnknown, "Deploying")[0]
+	eventType, _ := classifyEvent(watch.Modified, readyCondition(corev1.ConditionTrue, "Ready"), &previous)
+	if eventType != EventReady {
+		t.Errorf("eventType = %v, want %v", eventType, EventReady)
+	}
+}
+
+func TestClassifyEventModifiedBecomesFailed(t *testing.T) {
+	previous := readyCondition(corev1.ConditionUnknown, "Deploying")[0]
+	eventType, _ := classifyEvent(watch.Modified, readyCondition(corev1.ConditionFalse, "RevisionFailed"), &previous)
+	if eventType != EventFailed {
+		t.Errorf("eventType = %v, want %v", eventType, EventFailed)
+	}
+}
+
+func TestClassifyEventCoalescesUnchangedTransitions(t *testing.T) {
+	previous := readyCondition(corev1.ConditionTrue, "Ready")[0]
+	eventType, _ := classifyEvent(watch.Modified, readyCondition(corev1.ConditionTrue, "Ready"), &previous)
+	if eventType != "" {
+		t.Errorf("eventType = %v, want empty (coalesced)", eventType)
+	}
+}
+
+func TestClassifyEventModifiedWithNoReadyCondition(t *testing.T) {
+	eventType, ready := classifyEvent(watch.Modified, apis.Conditions{}, nil)
+	if eventType != EventModified {
+		t.Errorf("eventType = %v, want %v", eventType, EventModified)
+	}
+	if ready != nil {
+		t.Errorf("ready = %v, want nil", ready)
+	}
+}
+
+func TestWatchServicesPublishesAddedEvent(t *testing.T) {
+	cl := newTestClient()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	events, err := cl.WatchServices(ctx)
+	if err != nil {
+		t.Fatalf("WatchServices() error = %v", err)
+	}
+
+	service := namespacedServiceNamed("my-svc")
+	if err := cl.CreateService(service); err != nil {
+		t.Fatalf("CreateService() error = %v", err)
+	}
+
+	select {
+	case event := <-events:
+		if event.Type != EventAdded {
+			t.Errorf("Type = %v, want %v", event.Type, EventAdded)
+		}
+		if event.Object.Name != "my-svc" {
+			t.Errorf("Object.Name = %q, want my-svc", event.Object.Name)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for an Added event")
+	}
+}
+
+func TestWatchServicesClosesChannelOnContextCancel(t *testing.T) {
+	cl := newTestClient()
+	ctx, cancel := context.WithCancel(context.Background())
+
+	events, err := cl.WatchServices(ctx)
+	if err != nil {
+		t.Fatalf("WatchServices() error = %v", err)
+	}
+
+	cancel()
+
+	select {
+	case _, ok := <-events:
+		if ok {
+			t.Fatal("expected the events channel to close after ctx cancellation without delivering an event")
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for the events channel to close after ctx cancellation")
+	}
+}
+
+func TestWaitForServicesReportsReadyAndFailed(t *testing.T) {
+	ready := namespacedServiceNamed("ready-svc")
+	failed := namespacedServiceNamed("failed-svc")
+	cl := newTestClient(ready, failed)
+
+	resultCh := make(chan MultiWaitResult, 1)
+	go func() {
+		resultCh <- cl.WaitForServices([]string{"ready-svc", "failed-svc"}, 5*time.Second)
+	}()
+
+	// Give WaitForServices time to establish its watches before we publish
+	// the transitions it's waiting on.
+	time.Sleep(200 * time.Millisecond)
+
+	readyUpdate := ready.DeepCopy()
+	readyUpdate.Status.Conditions = []apis.Condition{{Type: apis.ConditionReady, Status: corev1.ConditionTrue}}
+	if err := cl.UpdateService(readyUpdate); err != nil {
+		t.Fatalf("UpdateService(ready) error = %v", err)
+	}
+
+	failedUpdate := failed.DeepCopy()
+	failedUpdate.Status.Conditions = []apis.Condition{{Type: apis.ConditionReady, Status: corev1.ConditionFalse, Reason: "RevisionFailed"}}
+	if err := cl.UpdateService(failedUpdate); err != nil {
+		t.Fatalf("UpdateService(failed) error = %v", err)
+	}
+
+	select {
+	case result := <-resultCh:
+		if len(result.Ready) != 1 || result.Ready[0] != "ready-svc" {
+			t.Errorf("Ready = %v, want [ready-svc]", result.Ready)
+		}
+		if _, ok := result.Failed["failed-svc"]; !ok {
+			t.Errorf("Failed = %v, want an entry for failed-svc", result.Failed)
+		}
+	case <-time.After(6 * time.Second):
+		t.Fatal("timed out waiting for WaitForServices to return")
+	}
+}